@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// hijackedStream is a raw, bidirectional connection to the Docker daemon
+// obtained by upgrading an HTTP request (attach, and later exec-start).
+// Reads yield the still-framed multiplexed stream; writes go straight to
+// the daemon as container stdin.
+type hijackedStream struct {
+	conn net.Conn
+	r    io.Reader
+}
+
+func (h *hijackedStream) Read(p []byte) (int, error)  { return h.r.Read(p) }
+func (h *hijackedStream) Write(p []byte) (int, error) { return h.conn.Write(p) }
+func (h *hijackedStream) Close() error                { return h.conn.Close() }
+
+// attachOptions describes an attach session: whether the container's main
+// process has a TTY (in which case the daemon sends a single raw byte
+// stream instead of multiplexed frames; attach() itself ignores this, and
+// bridge() reads it to choose between demuxing and a raw copy) and which
+// key sequence the daemon should treat as "detach without killing the
+// process" — see https://docs.docker.com/engine/reference/commandline/attach/#detach-keys.
+type attachOptions struct {
+	tty        bool
+	detachKeys string
+}
+
+// attach opens a bidirectional stream to a running container's main
+// process — the API equivalent of `docker attach`.
+func (c *dockerClient) attach(containerID string, opts attachOptions) (*hijackedStream, error) {
+	path := fmt.Sprintf("/containers/%s/attach?stream=1&stdin=1&stdout=1&stderr=1", containerID)
+	if opts.detachKeys != "" {
+		path += "&detachKeys=" + url.QueryEscape(opts.detachKeys)
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://docker"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.hijack(req)
+}
+
+// hijack issues req asking the daemon to upgrade the connection to a raw
+// duplex stream, as both attach and exec-start require.
+func (c *dockerClient) hijack(req *http.Request) (*hijackedStream, error) {
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	resp, conn, br, err := c.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("docker API %s %s: expected 101 Switching Protocols, got %s", req.Method, req.URL.Path, resp.Status)
+	}
+
+	// bufio.Reader may have buffered frame bytes past the headers during
+	// ReadResponse; prepend them so none of the stream is lost.
+	r := io.Reader(conn)
+	if n := br.Buffered(); n > 0 {
+		buffered := make([]byte, n)
+		_, _ = io.ReadFull(br, buffered)
+		r = io.MultiReader(bytes.NewReader(buffered), conn)
+	}
+	return &hijackedStream{conn: conn, r: r}, nil
+}
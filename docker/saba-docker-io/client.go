@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// errCanceled is returned by postCancelable when cancel fires before the
+// daemon responds.
+var errCanceled = errors.New("docker-io: request canceled")
+
+// dockerClient talks to the Docker Engine API over its unix socket. It
+// intentionally avoids a dependency on the docker/docker client SDK — the
+// bridge only ever needs a handful of endpoints, all reachable with plain
+// net/http framing over a dialed connection.
+type dockerClient struct {
+	sockPath string
+}
+
+func newDockerClient(sockPath string) *dockerClient {
+	return &dockerClient{sockPath: sockPath}
+}
+
+func (c *dockerClient) dial() (net.Conn, error) {
+	conn, err := net.Dial("unix", c.sockPath)
+	if err != nil {
+		return nil, fmt.Errorf("dial docker socket %s: %w", c.sockPath, err)
+	}
+	return conn, nil
+}
+
+// connClosingBody closes the underlying connection along with the response
+// body, since plain (non-hijacked) requests still hold the socket open for
+// the lifetime of a streamed response (e.g. logs).
+type connClosingBody struct {
+	io.ReadCloser
+	conn net.Conn
+}
+
+func (b *connClosingBody) Close() error {
+	_ = b.ReadCloser.Close()
+	return b.conn.Close()
+}
+
+// roundTrip sends req over a fresh connection and returns the raw response,
+// that connection, and the bufio.Reader used to read it. Callers that need
+// to hijack the connection (attach, exec start) must account for bytes the
+// reader may have already buffered past the response headers.
+func (c *dockerClient) roundTrip(req *http.Request) (*http.Response, net.Conn, *bufio.Reader, error) {
+	req.Host = "docker"
+
+	conn, err := c.dial()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("write request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("read response: %w", err)
+	}
+	return resp, conn, br, nil
+}
+
+// do sends req and returns its body for streaming. The caller must Close it
+// to release the underlying socket.
+func (c *dockerClient) do(req *http.Request) (io.ReadCloser, error) {
+	resp, conn, _, err := c.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("docker API %s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return &connClosingBody{ReadCloser: resp.Body, conn: conn}, nil
+}
+
+// get issues a GET request and returns its body for streaming.
+func (c *dockerClient) get(path string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+// post issues a POST request, optionally with a JSON body, and returns the
+// response body for streaming.
+func (c *dockerClient) post(path string, body io.Reader) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodPost, "http://docker"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req)
+}
+
+// postCancelable is like post, but aborts the underlying connection when
+// cancel fires: before the daemon responds it returns errCanceled, and
+// afterwards reads of the returned body fail. It's for long-polling
+// endpoints like /wait that can otherwise block indefinitely — the caller
+// is expected to close cancel once it no longer needs the call to complete.
+func (c *dockerClient) postCancelable(path string, cancel <-chan struct{}) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodPost, "http://docker"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Host = "docker"
+
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-cancel:
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	if err := req.Write(conn); err != nil {
+		close(stop)
+		conn.Close()
+		return nil, fmt.Errorf("write request: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		close(stop)
+		conn.Close()
+		select {
+		case <-cancel:
+			return nil, errCanceled
+		default:
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+	}
+	if resp.StatusCode >= 400 {
+		close(stop)
+		resp.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("docker API POST %s: %s", path, resp.Status)
+	}
+	return &connClosingBody{ReadCloser: resp.Body, conn: conn}, nil
+}
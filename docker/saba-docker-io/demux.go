@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Stream type byte used in the Docker Engine API's multiplexed attach/logs
+// frames (see streamHeaderSize below).
+const (
+	streamStdin  = 0
+	streamStdout = 1
+	streamStderr = 2
+)
+
+// streamHeaderSize is the length of the frame header the daemon prepends to
+// every chunk of a multiplexed stream: byte 0 is the stream type, bytes 1-3
+// are reserved/zero, and bytes 4-7 are a big-endian uint32 payload length.
+const streamHeaderSize = 8
+
+// demux reads a multiplexed Docker stream from src and writes each frame's
+// payload to stdout or stderr according to its stream type, stopping at EOF.
+// This only applies to containers created without a TTY; a TTY container's
+// attach/logs stream is raw bytes with no framing and should be copied to
+// stdout directly instead.
+func demux(src io.Reader, stdout, stderr io.Writer) error {
+	header := make([]byte, streamHeaderSize)
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		size := binary.BigEndian.Uint32(header[4:8])
+		dst := stdout
+		if header[0] == streamStderr {
+			dst = stderr
+		}
+		if _, err := io.CopyN(dst, src, int64(size)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func frame(streamType byte, payload string) []byte {
+	header := make([]byte, streamHeaderSize)
+	header[0] = streamType
+	binary.BigEndian.PutUint32(header[4:8], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestDemux(t *testing.T) {
+	tests := []struct {
+		name       string
+		frames     [][]byte
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "single stdout frame",
+			frames:     [][]byte{frame(streamStdout, "hello\n")},
+			wantStdout: "hello\n",
+		},
+		{
+			name:       "single stderr frame",
+			frames:     [][]byte{frame(streamStderr, "oops\n")},
+			wantStderr: "oops\n",
+		},
+		{
+			name: "interleaved stdout and stderr",
+			frames: [][]byte{
+				frame(streamStdout, "out1"),
+				frame(streamStderr, "err1"),
+				frame(streamStdout, "out2"),
+			},
+			wantStdout: "out1out2",
+			wantStderr: "err1",
+		},
+		{
+			name:       "empty payload frame",
+			frames:     [][]byte{frame(streamStdout, "")},
+			wantStdout: "",
+		},
+		{
+			name:       "unknown stream type falls back to stdout",
+			frames:     [][]byte{frame(streamStdin, "hi")},
+			wantStdout: "hi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var src bytes.Buffer
+			for _, f := range tt.frames {
+				src.Write(f)
+			}
+
+			var stdout, stderr bytes.Buffer
+			if err := demux(&src, &stdout, &stderr); err != nil {
+				t.Fatalf("demux: %v", err)
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestDemuxTruncatedHeader(t *testing.T) {
+	src := strings.NewReader("\x01\x00\x00")
+	var stdout, stderr bytes.Buffer
+	if err := demux(src, &stdout, &stderr); err == nil {
+		t.Fatal("expected error for a truncated frame header, got nil")
+	}
+}
+
+func TestDemuxTruncatedPayload(t *testing.T) {
+	full := frame(streamStdout, "hello")
+	src := bytes.NewReader(full[:len(full)-2])
+	var stdout, stderr bytes.Buffer
+	if err := demux(src, &stdout, &stderr); err == nil {
+		t.Fatal("expected error for a frame whose payload is cut short, got nil")
+	}
+}
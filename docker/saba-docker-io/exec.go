@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// execCreateRequest is the body of POST /containers/{id}/exec.
+type execCreateRequest struct {
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Cmd          []string `json:"Cmd"`
+}
+
+type execCreateResponse struct {
+	ID string `json:"Id"`
+}
+
+// execInspect is the subset of GET /exec/{id}/json we need.
+type execInspect struct {
+	Running  bool `json:"Running"`
+	ExitCode int  `json:"ExitCode"`
+}
+
+// createExec creates (but does not start) an exec instance that runs cmd
+// inside container, attached on all three streams.
+func (c *dockerClient) createExec(container string, cmd []string) (string, error) {
+	reqBody, err := json.Marshal(execCreateRequest{
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	body, err := c.post(fmt.Sprintf("/containers/%s/exec", container), bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	var created execCreateResponse
+	if err := json.NewDecoder(body).Decode(&created); err != nil {
+		return "", fmt.Errorf("decode exec create response: %w", err)
+	}
+	return created.ID, nil
+}
+
+// startExec starts execID non-detached and returns a hijacked, multiplexed
+// bridge to its stdin/stdout/stderr — the same framing an attach stream
+// uses.
+func (c *dockerClient) startExec(execID string) (*hijackedStream, error) {
+	req, err := http.NewRequest(http.MethodPost, "http://docker/exec/"+execID+"/start", bytes.NewReader([]byte(`{"Detach":false,"Tty":false}`)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.hijack(req)
+}
+
+// execExitCode inspects a finished exec instance and returns its exit code.
+func (c *dockerClient) execExitCode(execID string) (int, error) {
+	body, err := c.get("/exec/" + execID + "/json")
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	var inspect execInspect
+	if err := json.NewDecoder(body).Decode(&inspect); err != nil {
+		return 0, fmt.Errorf("decode exec inspect response: %w", err)
+	}
+	return inspect.ExitCode, nil
+}
+
+// runExec implements the `saba-docker-io exec <container> -- <cmd> [args...]`
+// form: it runs cmd inside an already-running container via the Docker
+// exec API instead of attaching to the container's main process, bridges
+// its streams the same way attach does, and exits with the exec's own exit
+// code. This lets saba-chan run supplementary commands (health probes,
+// admin CLIs, RCON-style tools) inside a managed container without
+// spawning a new one.
+func runExec(args []string) {
+	fs := flag.NewFlagSet("exec", flag.ExitOnError)
+	socket := fs.String("socket", defaultSocket, "path to the Docker Engine API unix socket")
+	fs.Parse(args)
+
+	rest := fs.Args()
+	dashDash := -1
+	for i, a := range rest {
+		if a == "--" {
+			dashDash = i
+			break
+		}
+	}
+	if dashDash < 0 || dashDash == 0 || dashDash == len(rest)-1 {
+		fmt.Fprintln(os.Stderr, "Usage: saba-docker-io exec [flags] <container_name> -- <cmd> [args...]")
+		os.Exit(1)
+	}
+	container := rest[dashDash-1]
+	cmd := rest[dashDash+1:]
+
+	client := newDockerClient(*socket)
+
+	execID, err := client.createExec(container, cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[saba-docker-io] exec create failed: %v\n", err)
+		os.Exit(2)
+	}
+
+	stream, err := client.startExec(execID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[saba-docker-io] exec start failed: %v\n", err)
+		os.Exit(2)
+	}
+
+	go func() {
+		_, _ = io.Copy(stream, os.Stdin)
+	}()
+	_ = demux(stream, os.Stdout, os.Stderr)
+	stream.Close()
+
+	exitCode, err := client.execExitCode(execID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[saba-docker-io] exec inspect failed: %v\n", err)
+		os.Exit(2)
+	}
+	os.Exit(exitCode)
+}
@@ -2,7 +2,8 @@
 //
 // Usage:
 //
-//	saba-docker-io <container_name> [docker_path]
+//	saba-docker-io [flags] <container_name>
+//	saba-docker-io exec [flags] <container_name> -- <cmd> [args...]
 //
 // This small Linux binary acts as a bridge between saba-chan's ManagedProcess
 // and a Docker container. It lives at /opt/saba-chan/docker/saba-docker-io
@@ -10,56 +11,70 @@
 //
 //	wsl -u root -- /opt/saba-chan/docker/saba-docker-io <container>
 //
-// Flow:
-//  1. Fetch recent log history via `docker logs --tail 200` → print to stdout
-//  2. exec() into `docker attach --sig-proxy=false` → bidirectional IO
-//     stdin  flows from saba-chan daemon → container
-//     stdout flows from container → saba-chan daemon
+// It talks to the Docker Engine API directly over its unix socket rather
+// than shelling out to the docker CLI, so it can demultiplex the stdout and
+// stderr frames of the attach/logs streams instead of merging them.
+//
+// By default it follows the container across restarts: when the attach
+// session ends (container exit, `docker restart`, a `--restart=on-failure`
+// bounce, …) it reissues the log-tail + attach sequence with exponential
+// backoff rather than exiting. Pass -no-follow to get the old behavior of
+// attaching once and exiting when that session ends.
+//
+// With -tty, the target container is assumed to have been created with a
+// TTY: the attach stream carries raw bytes instead of multiplexed frames
+// (so it's copied straight to stdout rather than demuxed), and window-size
+// hints framed on stdin (see resize.go) drive resize calls. There is no
+// local tty for this process to put in raw mode — it runs headless, piped
+// from the Windows host over wsl — so "raw mode" here just means we never
+// buffer or line-edit stdin/stdout ourselves; every byte is relayed as-is
+// and it's up to the daemon on the other end to manage echo/line
+// discipline.
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
-	"os/exec"
-	"syscall"
 )
 
 const (
-	defaultDocker  = "/opt/saba-chan/docker/docker"
+	defaultSocket  = "/var/run/docker.sock"
 	initialLogTail = "200"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: saba-docker-io <container_name> [docker_path]")
-		os.Exit(1)
+	if len(os.Args) >= 2 && os.Args[1] == "exec" {
+		runExec(os.Args[2:])
+		return
 	}
 
-	container := os.Args[1]
-	docker := defaultDocker
-	if len(os.Args) >= 3 {
-		docker = os.Args[2]
+	socket := flag.String("socket", defaultSocket, "path to the Docker Engine API unix socket")
+	tty := flag.Bool("tty", false, "the container's main process has a TTY; skip stream demuxing and forward resize hints")
+	detachKeys := flag.String("detach-keys", "", "key sequence the daemon should treat as a detach request (e.g. ctrl-p,ctrl-q)")
+	noFollow := flag.Bool("no-follow", false, "attach once and exit when that attach session ends, instead of reattaching across restarts")
+	maxRestarts := flag.Int("max-restarts", 0, "give up after this many reattach attempts (0 = unlimited)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: saba-docker-io [flags] <container_name>")
+		flag.PrintDefaults()
+		os.Exit(1)
 	}
+	container := flag.Arg(0)
 
-	// ── Phase 1: Fetch recent log history ───────────────────
-	// Non-fatal: container may have no logs yet.
-	logs := exec.Command(docker, "logs", "--tail", initialLogTail, "--timestamps", container)
-	logs.Stdout = os.Stdout
-	logs.Stderr = os.Stdout // merge container stderr into our stdout
-	_ = logs.Run()
+	client := newDockerClient(*socket)
+	opts := attachOptions{tty: *tty, detachKeys: *detachKeys}
 
-	// ── Phase 2: exec into docker attach (replaces this process) ──
-	// Using syscall.Exec so this process becomes docker attach directly.
-	// stdin/stdout/stderr are inherited automatically — zero-copy IO.
-	dockerPath, err := exec.LookPath(docker)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[saba-docker-io] docker not found at %s: %v\n", docker, err)
-		os.Exit(2)
+	if *noFollow {
+		showLogHistory(client, container, *tty)
+		stdinDst, hints := startStdinPump(*tty)
+		if _, err := bridge(container, opts, stdinDst, hints, clientDeps(client)); err != nil {
+			fmt.Fprintf(os.Stderr, "[saba-docker-io] %v\n", err)
+			os.Exit(2)
+		}
+		return
 	}
 
-	argv := []string{docker, "attach", "--sig-proxy=false", container}
-	if err := syscall.Exec(dockerPath, argv, os.Environ()); err != nil {
-		fmt.Fprintf(os.Stderr, "[saba-docker-io] exec failed: %v\n", err)
-		os.Exit(3)
-	}
+	followSession(client, container, opts, *maxRestarts)
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Window-size hints for a TTY-attached container arrive on stdin, framed as
+// an OSC-style escape sequence so they can share the pipe with real input
+// without colliding with anything the container might type back:
+//
+//	\x1b]saba;resize;<cols>;<rows>\x07
+//
+// stdinFilter strips these frames out before relaying stdin to the
+// container and reports them on a resizeHint channel instead.
+var resizeEscapePrefix = []byte("\x1b]saba;resize;")
+
+const resizeEscapeSuffix = '\a'
+
+// resizeHint is a parsed window-size hint from the daemon's side channel.
+type resizeHint struct {
+	cols, rows uint
+}
+
+// stdinFilter relays src to dst byte-for-byte, except for resize escape
+// frames, which are extracted and sent to hints instead of forwarded. done
+// lets the caller stop it from blocking on a hints send once nothing is
+// reading hints anymore; closing it makes stdinFilter return nil at that
+// send. startStdinPump passes a channel it never closes.
+func stdinFilter(src io.Reader, dst io.Writer, hints chan<- resizeHint, done <-chan struct{}) error {
+	r := bufio.NewReader(src)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if b != resizeEscapePrefix[0] {
+			if _, werr := dst.Write([]byte{b}); werr != nil {
+				return werr
+			}
+			continue
+		}
+
+		rest, err := r.Peek(len(resizeEscapePrefix) - 1)
+		if err != nil || !bytes.Equal(rest, resizeEscapePrefix[1:]) {
+			// Not actually a resize frame; forward the byte we already
+			// consumed and let the next iteration re-examine the rest.
+			if _, werr := dst.Write([]byte{b}); werr != nil {
+				return werr
+			}
+			continue
+		}
+		if _, err := r.Discard(len(rest)); err != nil {
+			return err
+		}
+
+		frame, err := r.ReadBytes(resizeEscapeSuffix)
+		if err != nil {
+			return err
+		}
+		var cols, rows uint
+		if _, err := fmt.Sscanf(string(frame), "%d;%d\a", &cols, &rows); err == nil {
+			select {
+			case hints <- resizeHint{cols: cols, rows: rows}:
+			case <-done:
+				return nil
+			}
+		}
+	}
+}
+
+// watchResize issues a resize call to the daemon each time a hint arrives,
+// keeping the container's pty in sync with the host terminal size. Errors
+// are logged and otherwise ignored — a missed resize isn't fatal. It
+// returns once done is closed, so bridge() can stop it when the attach
+// session ends instead of leaking it across reattaches.
+func (c *dockerClient) watchResize(containerID string, hints <-chan resizeHint, done <-chan struct{}) {
+	for {
+		select {
+		case hint := <-hints:
+			path := fmt.Sprintf("/containers/%s/resize?h=%d&w=%d", containerID, hint.rows, hint.cols)
+			body, err := c.post(path, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[saba-docker-io] resize failed: %v\n", err)
+				continue
+			}
+			body.Close()
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStdinFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantOut   string
+		wantHints []resizeHint
+	}{
+		{
+			name:    "plain input passes through untouched",
+			input:   "hello world\n",
+			wantOut: "hello world\n",
+		},
+		{
+			name:      "single resize frame is stripped and reported",
+			input:     "\x1b]saba;resize;80;24\x07",
+			wantHints: []resizeHint{{cols: 80, rows: 24}},
+		},
+		{
+			name:      "resize frame surrounded by real input",
+			input:     "before\x1b]saba;resize;120;40\x07after",
+			wantOut:   "beforeafter",
+			wantHints: []resizeHint{{cols: 120, rows: 40}},
+		},
+		{
+			name:      "two resize frames",
+			input:     "\x1b]saba;resize;1;2\x07\x1b]saba;resize;3;4\x07",
+			wantHints: []resizeHint{{cols: 1, rows: 2}, {cols: 3, rows: 4}},
+		},
+		{
+			name:    "lone ESC that isn't a resize frame is forwarded",
+			input:   "\x1bnot-a-resize-frame",
+			wantOut: "\x1bnot-a-resize-frame",
+		},
+		{
+			name:    "ESC right at EOF is forwarded, not dropped",
+			input:   "tail\x1b",
+			wantOut: "tail\x1b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := make(chan resizeHint, len(tt.wantHints))
+			done := make(chan struct{})
+			var out bytes.Buffer
+
+			if err := stdinFilter(bytes.NewReader([]byte(tt.input)), &out, hints, done); err != nil {
+				t.Fatalf("stdinFilter: %v", err)
+			}
+			close(hints)
+
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("output = %q, want %q", got, tt.wantOut)
+			}
+
+			var gotHints []resizeHint
+			for h := range hints {
+				gotHints = append(gotHints, h)
+			}
+			if len(gotHints) != len(tt.wantHints) {
+				t.Fatalf("hints = %v, want %v", gotHints, tt.wantHints)
+			}
+			for i, h := range gotHints {
+				if h != tt.wantHints[i] {
+					t.Errorf("hint[%d] = %v, want %v", i, h, tt.wantHints[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStdinFilterStopsOnDone(t *testing.T) {
+	hints := make(chan resizeHint) // unbuffered and unread, so a send blocks
+	done := make(chan struct{})
+	close(done)
+
+	in := "\x1b]saba;resize;80;24\x07"
+	if err := stdinFilter(bytes.NewReader([]byte(in)), &bytes.Buffer{}, hints, done); err != nil {
+		t.Fatalf("stdinFilter: %v", err)
+	}
+}
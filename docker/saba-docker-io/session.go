@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Backoff parameters for followSession's reattach loop.
+const (
+	backoffStart = 500 * time.Millisecond
+	backoffCap   = 30 * time.Second
+	healthySince = 60 * time.Second
+)
+
+// bridgeDeps is the subset of dockerClient's API that bridge() needs,
+// pulled out as function values so tests can fake the Docker calls and
+// drive bridge() through several reattach cycles without a real socket.
+// clientDeps builds the production implementation.
+type bridgeDeps struct {
+	attach      func(container string, opts attachOptions) (io.ReadWriteCloser, error)
+	wait        func(container string, cancel <-chan struct{}) (int, error)
+	watchResize func(container string, hints <-chan resizeHint, done <-chan struct{})
+}
+
+func clientDeps(client *dockerClient) bridgeDeps {
+	return bridgeDeps{
+		attach: func(container string, opts attachOptions) (io.ReadWriteCloser, error) {
+			return client.attach(container, opts)
+		},
+		wait:        client.wait,
+		watchResize: client.watchResize,
+	}
+}
+
+// showLogHistory fetches the container's recent log history and prints it.
+// Non-fatal: the container may have no logs yet.
+func showLogHistory(client *dockerClient, container string, tty bool) {
+	body, err := client.get(fmt.Sprintf("/containers/%s/logs?stdout=1&stderr=1&timestamps=1&tail=%s", container, initialLogTail))
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	if tty {
+		_, _ = io.Copy(os.Stdout, body)
+	} else {
+		_ = demux(body, os.Stdout, os.Stderr)
+	}
+}
+
+// bridge attaches to the container and relays stdin/stdout/stderr until the
+// container stops or the stream otherwise closes (e.g. the operator
+// detached), whichever happens first. It reports whether the container was
+// actually observed to exit (via wait()) as opposed to the session simply
+// closing some other way, and always stops the wait() long poll and, in
+// -tty mode, the resize watcher before returning — otherwise either would
+// keep running (and, for wait, keep a socket open) for as long as the
+// container itself stays up, leaking both across every reattach.
+//
+// Stdin is not read here: stdinDst is a shared swappableWriter fed by a
+// single long-lived reader started once by startStdinPump (see stdin.go).
+// bridge only points it at this session's stream for the duration of the
+// call, so a reattach retargets stdin instead of spawning another reader
+// on top of the stdin fd.
+func bridge(container string, opts attachOptions, stdinDst *swappableWriter, hints chan resizeHint, deps bridgeDeps) (exited bool, err error) {
+	stream, err := deps.attach(container, opts)
+	if err != nil {
+		return false, fmt.Errorf("attach failed: %w", err)
+	}
+	defer stream.Close()
+
+	stdinDst.set(stream)
+	defer stdinDst.set(nil)
+
+	done := make(chan struct{})
+	exitedCh := make(chan bool, 1)
+	go func() {
+		_, werr := deps.wait(container, done)
+		exitedCh <- werr == nil
+		stream.Close()
+	}()
+
+	if opts.tty {
+		go deps.watchResize(container, hints, done)
+		_, err = io.Copy(os.Stdout, stream)
+	} else {
+		err = demux(stream, os.Stdout, os.Stderr)
+	}
+
+	// The IO loop above has ended. Stop the wait() long poll (and resize
+	// watcher) now, whether it ended because the container actually exited
+	// or for some other reason, then find out which it was.
+	stream.Close()
+	close(done)
+	exited = <-exitedCh
+	return exited, err
+}
+
+// followSession keeps attaching to container, reattaching with exponential
+// backoff each time the session ends — whether because the container
+// restarted (`--restart=on-failure`, a manual `docker restart`) or the
+// session merely closed (e.g. the operator detached) — until maxRestarts
+// reattach attempts have been made (0 = unlimited). Backoff resets once a
+// session has stayed up for healthySince.
+func followSession(client *dockerClient, container string, opts attachOptions, maxRestarts int) {
+	stdinDst, hints := startStdinPump(opts.tty)
+	deps := clientDeps(client)
+
+	backoff := backoffStart
+	restarts := 0
+
+	for {
+		// Reissue the log tail on every reattach, not just the first one —
+		// after a real restart it's the new container instance's history,
+		// and the operator should see it just like on the initial attach.
+		showLogHistory(client, container, opts.tty)
+
+		start := time.Now()
+		exited, err := bridge(container, opts, stdinDst, hints, deps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[saba-docker-io] %v\n", err)
+		}
+
+		if time.Since(start) >= healthySince {
+			backoff = backoffStart
+		}
+
+		restarts++
+		if maxRestarts > 0 && restarts > maxRestarts {
+			return
+		}
+
+		// exited tells us whether the container itself stopped (a real
+		// restart) as opposed to the session merely closing some other way
+		// (e.g. the operator detached) — the two warrant different
+		// operator-facing messages.
+		if exited {
+			fmt.Fprintln(os.Stderr, "[saba-docker-io] container restarted, reattaching…")
+		} else {
+			fmt.Fprintln(os.Stderr, "[saba-docker-io] attach session ended, reattaching…")
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > backoffCap {
+			backoff = backoffCap
+		}
+	}
+}
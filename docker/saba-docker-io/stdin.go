@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// swappableWriter forwards Write calls to whichever destination is
+// currently set via set, silently dropping bytes written while nothing is
+// set (e.g. the gap between a detach and the next reattach, or a write
+// that loses the race with the destination being cleared). It always
+// reports success so a long-lived io.Copy or stdinFilter loop writing
+// through it never aborts just because the current destination went away
+// — that's expected during a reattach, not an error.
+type swappableWriter struct {
+	mu  sync.Mutex
+	dst io.Writer
+}
+
+func (w *swappableWriter) set(dst io.Writer) {
+	w.mu.Lock()
+	w.dst = dst
+	w.mu.Unlock()
+}
+
+func (w *swappableWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	dst := w.dst
+	w.mu.Unlock()
+	if dst != nil {
+		_, _ = dst.Write(p)
+	}
+	return len(p), nil
+}
+
+// startStdinPump reads os.Stdin exactly once, for the life of the process,
+// and relays it into whichever stream the returned swappableWriter is
+// currently pointed at. Each bridge() call retargets the writer to its own
+// attach stream instead of starting a fresh stdin reader (and, in -tty
+// mode, a fresh bufio.Reader with its own buffered lookahead) on every
+// reattach — which is what used to leak a goroutine per reattach and could
+// hand a stale session the operator's next keystrokes.
+//
+// In -tty mode it also returns the shared resize-hint channel that
+// stdinFilter feeds; each bridge() call's watchResize drains it for the
+// life of that one session.
+func startStdinPump(tty bool) (dst *swappableWriter, hints chan resizeHint) {
+	dst = &swappableWriter{}
+	if !tty {
+		go func() { _, _ = io.Copy(dst, os.Stdin) }()
+		return dst, nil
+	}
+
+	hints = make(chan resizeHint, 1)
+	// stdinFilter's done only needs to stop it from blocking on a hints
+	// send while nothing is consuming hints; it's never closed here
+	// because the pump itself is meant to outlive every individual
+	// session, not just one.
+	go func() { _ = stdinFilter(os.Stdin, dst, hints, make(chan struct{})) }()
+	return dst, hints
+}
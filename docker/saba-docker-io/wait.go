@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// waitResult is the subset of the /containers/{id}/wait response we care
+// about.
+type waitResult struct {
+	StatusCode int `json:"StatusCode"`
+}
+
+// wait blocks until the container stops and returns its exit code, or
+// returns an error as soon as cancel is closed (errCanceled if the daemon
+// had not yet answered, otherwise a decode error) — which bridge() does
+// once its attach session ends for any reason, so this long poll (and its
+// socket) never outlives the session that started it.
+func (c *dockerClient) wait(containerID string, cancel <-chan struct{}) (int, error) {
+	body, err := c.postCancelable(fmt.Sprintf("/containers/%s/wait", containerID), cancel)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	var result waitResult
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decode wait response: %w", err)
+	}
+	return result.StatusCode, nil
+}